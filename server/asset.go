@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ErrAssetNotFound is returned by AssetStore.Get when no asset exists for
+// the given ID.
+var ErrAssetNotFound = errors.New("asset not found")
+
+// AssetStore persists binary attachments the LLM produces or consumes
+// (transcribed audio, generated diagrams, uploaded PDFs summarized into
+// threads) and hands them back by opaque ID. Implementations: a Postgres
+// large-object backend (asset_postgres.go) and an S3-compatible backend
+// (asset_s3.go).
+type AssetStore interface {
+	Set(id string, from io.Reader) error
+	Get(id string, to io.Writer) error
+	Delete(id string) error
+}
+
+// Asset is a row of LLM_Assets: metadata about a blob stored in the
+// configured AssetStore and tied to the thread that produced it.
+type Asset struct {
+	AssetID    string
+	RootPostID string
+	Kind       string
+	MimeType   string
+	CreatedAt  int64
+}
+
+// setupAssetStore constructs p.assetStore from AssetStoreBackend and starts
+// the background sweep (asset_sweep.go) that reclaims blobs for deleted
+// posts. Called once from SetupDB; without this, p.assetStore stays nil and
+// saveAsset/getAsset panic the first time they're used.
+func (p *Plugin) setupAssetStore(ctx context.Context) error {
+	switch backend := p.getConfiguration().AssetStoreBackend; backend {
+	case "", "postgres":
+		p.assetStore = newPostgresAssetStore(p.dbMaster)
+	case "s3":
+		awsConfig, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config for asset store: %w", err)
+		}
+		p.assetStore = newS3AssetStore(s3.NewFromConfig(awsConfig), p.getConfiguration().AssetStoreS3Bucket, p.getConfiguration().AssetStoreS3Prefix)
+	default:
+		return fmt.Errorf("unknown asset store backend %q", backend)
+	}
+
+	p.StartAssetSweep(ctx)
+
+	return nil
+}
+
+// saveAsset writes the blob to the plugin's configured AssetStore and
+// records it in LLM_Assets. ON DELETE CASCADE from Posts only removes this
+// metadata row when the owning thread is deleted — see asset_sweep.go for
+// what actually reclaims the blob itself.
+func (p *Plugin) saveAsset(rootPostID, kind, mimeType string, from io.Reader) (string, error) {
+	assetID := model.NewId()
+
+	if err := p.assetStore.Set(assetID, from); err != nil {
+		return "", fmt.Errorf("failed to store asset: %w", err)
+	}
+
+	if _, err := p.execBuilder(p.builder.Insert("LLM_Assets").
+		Columns("AssetID", "RootPostID", "Kind", "MimeType", "CreatedAt").
+		Values(assetID, rootPostID, kind, mimeType, model.GetMillis()),
+	); err != nil {
+		return "", fmt.Errorf("failed to record asset: %w", err)
+	}
+
+	return assetID, nil
+}
+
+// getAsset streams a previously saved asset to w.
+func (p *Plugin) getAsset(assetID string, to io.Writer) error {
+	return p.assetStore.Get(assetID, to)
+}
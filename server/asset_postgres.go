@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// postgresAssetStore stores assets as Postgres large objects, keeping them
+// in the same database as LLM_Post_Embeddings so a single backup/restore
+// covers both. LLM_Asset_Objects maps our opaque asset IDs to the OIDs
+// Postgres uses internally for large objects.
+type postgresAssetStore struct {
+	db *sqlx.DB
+}
+
+func newPostgresAssetStore(db *sqlx.DB) *postgresAssetStore {
+	return &postgresAssetStore{db: db}
+}
+
+func (s *postgresAssetStore) Set(id string, from io.Reader) error {
+	return s.withTx(func(ctx context.Context, tx pgx.Tx) error {
+		lobjs := tx.LargeObjects()
+
+		oid, err := lobjs.Create(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("failed to create large object: %w", err)
+		}
+
+		obj, err := lobjs.Open(ctx, oid, pgx.LargeObjectModeWrite)
+		if err != nil {
+			return fmt.Errorf("failed to open large object for write: %w", err)
+		}
+		if _, err := io.Copy(obj, from); err != nil {
+			return fmt.Errorf("failed to write large object: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO LLM_Asset_Objects (AssetID, OID) VALUES ($1, $2)
+			ON CONFLICT (AssetID) DO UPDATE SET OID = EXCLUDED.OID
+		`, id, oid)
+		return err
+	})
+}
+
+func (s *postgresAssetStore) Get(id string, to io.Writer) error {
+	return s.withTx(func(ctx context.Context, tx pgx.Tx) error {
+		oid, err := s.lookupOID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		obj, err := tx.LargeObjects().Open(ctx, oid, pgx.LargeObjectModeRead)
+		if err != nil {
+			return fmt.Errorf("failed to open large object for read: %w", err)
+		}
+		_, err = io.Copy(to, obj)
+		return err
+	})
+}
+
+func (s *postgresAssetStore) Delete(id string) error {
+	return s.withTx(func(ctx context.Context, tx pgx.Tx) error {
+		oid, err := s.lookupOID(ctx, tx, id)
+		if errors.Is(err, ErrAssetNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.LargeObjects().Unlink(ctx, oid); err != nil {
+			return fmt.Errorf("failed to unlink large object: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM LLM_Asset_Objects WHERE AssetID = $1`, id)
+		return err
+	})
+}
+
+func (s *postgresAssetStore) lookupOID(ctx context.Context, tx pgx.Tx, id string) (uint32, error) {
+	var oid uint32
+	err := tx.QueryRow(ctx, `SELECT OID FROM LLM_Asset_Objects WHERE AssetID = $1`, id).Scan(&oid)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrAssetNotFound
+	}
+	return oid, err
+}
+
+// withTx runs fn inside a transaction on a native pgx connection, since
+// large object access (lo_* functions) requires one for its lifetime.
+func (s *postgresAssetStore) withTx(fn func(ctx context.Context, tx pgx.Tx) error) error {
+	ctx := context.Background()
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pc := driverConn.(*stdlib.Conn).Conn()
+
+		tx, err := pc.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3AssetStore stores assets as objects in an S3-compatible bucket, one
+// object per asset ID. Works against real S3 or a local dev shim.
+type s3AssetStore struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newS3AssetStore(client *s3.Client, bucket, prefix string) *s3AssetStore {
+	return &s3AssetStore{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *s3AssetStore) key(id string) string {
+	return s.prefix + id
+}
+
+func (s *s3AssetStore) Set(id string, from io.Reader) error {
+	buf, err := io.ReadAll(from)
+	if err != nil {
+		return fmt.Errorf("failed to read asset body: %w", err)
+	}
+
+	_, err = s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(id)),
+		Body:   bytes.NewReader(buf),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put asset: %w", err)
+	}
+
+	return nil
+}
+
+func (s *s3AssetStore) Get(id string, to io.Writer) error {
+	out, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(id)),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return ErrAssetNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to get asset: %w", err)
+	}
+	defer out.Body.Close()
+
+	_, err = io.Copy(to, out.Body)
+	return err
+}
+
+func (s *s3AssetStore) Delete(id string) error {
+	_, err := s.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(id)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// assetSweepInterval controls how often sweepDeletedAssets runs.
+const assetSweepInterval = time.Hour
+
+// StartAssetSweep launches a background loop that reclaims the AssetStore
+// blob for every asset whose owning post has been deleted. ON DELETE CASCADE
+// on LLM_Assets only removes our metadata row when a post is hard-deleted —
+// it has no way to reach into the configured AssetStore and lo_unlink the
+// large object or delete the S3 key, and posts usually sit with DeleteAt set
+// long before (if ever) they're hard-deleted by retention. The loop exits
+// once ctx is done.
+func (p *Plugin) StartAssetSweep(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(assetSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.sweepDeletedAssets(ctx); err != nil {
+					p.API.LogError("asset sweep failed: " + err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// sweepDeletedAssets deletes the AssetStore blob and LLM_Assets row for
+// every asset whose owning post has been soft-deleted or no longer exists.
+// Deleting the row ourselves (rather than waiting on a future hard delete to
+// trigger ON DELETE CASCADE) is what actually gives assetStore.Delete a
+// chance to run.
+func (p *Plugin) sweepDeletedAssets(ctx context.Context) error {
+	var assetIDs []string
+	if err := p.dbMaster.SelectContext(ctx, &assetIDs, `
+		SELECT a.AssetID FROM LLM_Assets a
+		LEFT JOIN Posts p ON p.Id = a.RootPostID
+		WHERE p.Id IS NULL OR p.DeleteAt != 0
+	`); err != nil {
+		return fmt.Errorf("failed to find assets to sweep: %w", err)
+	}
+
+	for _, assetID := range assetIDs {
+		if err := p.assetStore.Delete(assetID); err != nil && !errors.Is(err, ErrAssetNotFound) {
+			p.API.LogError("failed to delete swept asset blob", "assetID", assetID, "error", err.Error())
+			continue
+		}
+
+		if _, err := p.execBuilder(p.builder.Delete("LLM_Assets").Where(sq.Eq{"AssetID": assetID})); err != nil {
+			p.API.LogError("failed to delete swept asset metadata", "assetID", assetID, "error", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+)
+
+// BackfillStatus is a point-in-time snapshot of a running backfill job,
+// reported by the `backfill status` slash command (runBackfillStatusCommand).
+// It is not exported as metrics.
+type BackfillStatus struct {
+	Running        bool
+	Paused         bool
+	Done           bool
+	Err            error
+	PostsProcessed int64
+	PostsTotal     int64
+}
+
+// BackfillJob streams posts in batches, embeds them, and bulk-loads the
+// results into the target provider's embedding table via a staging table
+// so indexing years of history doesn't hammer the DB with per-row inserts.
+type BackfillJob struct {
+	p          *Plugin
+	provider   EmbeddingProvider
+	channelIDs []string
+	batchSize  int
+
+	cancel     context.CancelFunc
+	paused     atomic.Bool
+	resume     chan struct{}
+	onComplete func()
+
+	processed atomic.Int64
+	total     atomic.Int64
+
+	done   atomic.Bool
+	jobErr atomic.Pointer[error]
+}
+
+// BackfillEmbeddings starts (or returns the already-running) backfill job
+// for the given channels, against the plugin's currently configured
+// embedding provider. Only one job runs at a time; call Pause/Resume on the
+// returned job to control it.
+func (p *Plugin) BackfillEmbeddings(ctx context.Context, channelIDs []string, batchSize int) (*BackfillJob, error) {
+	return p.backfillToProvider(ctx, p.getEmbeddingProvider(), channelIDs, batchSize)
+}
+
+// currentBackfillJob returns the in-flight backfill job, if any. p.backfillJob
+// is mutated under p.mu by backfillToProvider, so every reader goes through
+// this accessor rather than reading the field directly.
+func (p *Plugin) currentBackfillJob() *BackfillJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.backfillJob
+}
+
+// backfillToProvider is the shared implementation behind BackfillEmbeddings
+// and the re-embed path used when switching providers (see embedding.go).
+func (p *Plugin) backfillToProvider(ctx context.Context, provider EmbeddingProvider, channelIDs []string, batchSize int) (*BackfillJob, error) {
+	if provider == nil {
+		return nil, ErrNoEmbeddingProvider
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batchSize must be greater than zero, got %d", batchSize)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.backfillJob != nil && !p.backfillJob.done.Load() {
+		return p.backfillJob, nil
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	job := &BackfillJob{
+		p:          p,
+		provider:   provider,
+		channelIDs: channelIDs,
+		batchSize:  batchSize,
+		cancel:     cancel,
+		resume:     make(chan struct{}),
+	}
+
+	total, err := p.countBackfillCandidates(ctx, provider, channelIDs)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to count posts to backfill: %w", err)
+	}
+	job.total.Store(total)
+	p.backfillJob = job
+
+	go job.run(jobCtx)
+
+	return job, nil
+}
+
+// Pause halts the job after its current batch; Resume lets it continue.
+func (j *BackfillJob) Pause() {
+	j.paused.Store(true)
+}
+
+// Resume continues a paused job.
+func (j *BackfillJob) Resume() {
+	if j.paused.CompareAndSwap(true, false) {
+		j.resume <- struct{}{}
+	}
+}
+
+// Status returns a snapshot of the job's progress. Once the job has
+// stopped (Done), Running is false and Err reports why, if it wasn't a
+// clean finish.
+func (j *BackfillJob) Status() BackfillStatus {
+	done := j.done.Load()
+
+	var jobErr error
+	if errPtr := j.jobErr.Load(); errPtr != nil {
+		jobErr = *errPtr
+	}
+
+	return BackfillStatus{
+		Running:        !done,
+		Paused:         j.paused.Load(),
+		Done:           done,
+		Err:            jobErr,
+		PostsProcessed: j.processed.Load(),
+		PostsTotal:     j.total.Load(),
+	}
+}
+
+// finish marks the job as stopped, recording err (nil for a clean finish).
+func (j *BackfillJob) finish(err error) {
+	if err != nil {
+		j.jobErr.Store(&err)
+	}
+	j.done.Store(true)
+}
+
+func (j *BackfillJob) run(ctx context.Context) {
+	for {
+		if j.paused.Load() {
+			select {
+			case <-ctx.Done():
+				j.finish(ctx.Err())
+				return
+			case <-j.resume:
+			}
+		}
+
+		postIDs, texts, err := j.p.nextBackfillBatch(ctx, j.provider, j.channelIDs, j.batchSize)
+		if err != nil {
+			j.p.API.LogError("embedding backfill batch failed: " + err.Error())
+			j.finish(err)
+			return
+		}
+		if len(postIDs) == 0 {
+			if j.onComplete != nil {
+				j.onComplete()
+			}
+			j.finish(nil)
+			return
+		}
+
+		embeddings, err := j.provider.Embed(ctx, texts)
+		if err != nil {
+			j.p.API.LogError("embedding backfill provider failed: " + err.Error())
+			j.finish(err)
+			return
+		}
+
+		if err := j.p.copyEmbeddingsToStaging(ctx, j.provider, postIDs, embeddings); err != nil {
+			j.p.API.LogError("embedding backfill copy failed: " + err.Error())
+			j.finish(err)
+			return
+		}
+
+		if err := j.p.flushEmbeddingStaging(ctx, j.provider); err != nil {
+			j.p.API.LogError("embedding backfill flush failed: " + err.Error())
+			j.finish(err)
+			return
+		}
+
+		j.processed.Add(int64(len(postIDs)))
+	}
+}
+
+func (p *Plugin) countBackfillCandidates(ctx context.Context, provider EmbeddingProvider, channelIDs []string) (int64, error) {
+	var total int64
+	err := p.dbMaster.GetContext(ctx, &total, fmt.Sprintf(`
+		SELECT COUNT(*) FROM Posts p
+		WHERE p.ChannelID = ANY($1) AND p.DeleteAt = 0
+		AND NOT EXISTS (SELECT 1 FROM %s e WHERE e.PostID = p.Id)
+	`, embeddingTableName(provider)), pq.Array(channelIDs))
+	return total, err
+}
+
+// nextBackfillBatch returns the next batchSize posts still missing an
+// embedding. It deliberately has no OFFSET: the NOT EXISTS already shrinks
+// the candidate set as each batch is flushed, so "the next batch" is always
+// "whatever's still missing", not a fixed-size window that slides past
+// never-embedded posts as the un-embedded tail shrinks underneath it.
+func (p *Plugin) nextBackfillBatch(ctx context.Context, provider EmbeddingProvider, channelIDs []string, batchSize int) ([]string, []string, error) {
+	type row struct {
+		PostID  string `db:"postid"`
+		Message string `db:"message"`
+	}
+	var rows []row
+	if err := p.dbMaster.SelectContext(ctx, &rows, fmt.Sprintf(`
+		SELECT p.Id AS postid, p.Message AS message FROM Posts p
+		WHERE p.ChannelID = ANY($1) AND p.DeleteAt = 0
+		AND NOT EXISTS (SELECT 1 FROM %s e WHERE e.PostID = p.Id)
+		ORDER BY p.Id
+		LIMIT $2
+	`, embeddingTableName(provider)), pq.Array(channelIDs), batchSize); err != nil {
+		return nil, nil, err
+	}
+
+	postIDs := make([]string, len(rows))
+	texts := make([]string, len(rows))
+	for i, r := range rows {
+		postIDs[i] = r.PostID
+		texts[i] = r.Message
+	}
+	return postIDs, texts, nil
+}
+
+// copyEmbeddingsToStaging bulk-loads a batch into the provider's staging
+// table with pgx's CopyFrom, avoiding a per-row INSERT round trip.
+func (p *Plugin) copyEmbeddingsToStaging(ctx context.Context, provider EmbeddingProvider, postIDs []string, embeddings [][]float32) error {
+	conn, err := p.dbMaster.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stagingTable := embeddingStagingTableName(provider)
+	return conn.Raw(func(driverConn interface{}) error {
+		pc := driverConn.(*stdlib.Conn).Conn()
+
+		// CopyFrom always speaks COPY ... FORMAT binary, so vector_recv on the
+		// other end needs pgvector's binary codec registered on this
+		// connection — a plain "[1,2,3]" string (postgresEmbeddingFormat) is
+		// only valid for text-format parameters such as saveEmbedding's INSERT.
+		if err := pgvector.RegisterTypes(ctx, pc); err != nil {
+			return fmt.Errorf("failed to register vector type: %w", err)
+		}
+
+		rows := make([][]interface{}, len(postIDs))
+		for i, postID := range postIDs {
+			rows[i] = []interface{}{postID, pgvector.NewVector(embeddings[i])}
+		}
+
+		// pgx.Identifier quotes the name, so match the lower-case name
+		// Postgres folded the unquoted CREATE TABLE in ensureEmbeddingTable to.
+		_, err := pc.CopyFrom(ctx,
+			pgx.Identifier{strings.ToLower(stagingTable)},
+			[]string{"postid", "embedding"},
+			pgx.CopyFromRows(rows),
+		)
+		return err
+	})
+}
+
+// flushEmbeddingStaging moves the staged batch into the provider's real
+// table in one statement and clears staging for the next batch.
+func (p *Plugin) flushEmbeddingStaging(ctx context.Context, provider EmbeddingProvider) error {
+	table := embeddingTableName(provider)
+	stagingTable := embeddingStagingTableName(provider)
+
+	if _, err := p.dbMaster.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s (PostID, Embedding)
+		SELECT PostID, Embedding FROM %s
+		ON CONFLICT (PostID) DO UPDATE SET Embedding = EXCLUDED.Embedding
+	`, table, stagingTable)); err != nil {
+		return fmt.Errorf("failed to flush embedding staging: %w", err)
+	}
+
+	_, err := p.dbMaster.ExecContext(ctx, fmt.Sprintf(`TRUNCATE %s`, stagingTable))
+	return err
+}
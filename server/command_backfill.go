@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// runBackfillStartCommand starts an embedding backfill over channelIDs,
+// or reports the already-running job if one exists.
+func (p *Plugin) runBackfillStartCommand(channelIDs []string, batchSize int) (string, error) {
+	job, err := p.BackfillEmbeddings(context.Background(), channelIDs, batchSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to start embedding backfill: %w", err)
+	}
+
+	return formatBackfillStatus(job.Status()), nil
+}
+
+// runBackfillPauseCommand pauses the running backfill job after its current batch.
+func (p *Plugin) runBackfillPauseCommand() (string, error) {
+	job := p.currentBackfillJob()
+	if job == nil {
+		return "", fmt.Errorf("no embedding backfill is running")
+	}
+	job.Pause()
+	return "Backfill paused.", nil
+}
+
+// runBackfillResumeCommand resumes a paused backfill job.
+func (p *Plugin) runBackfillResumeCommand() (string, error) {
+	job := p.currentBackfillJob()
+	if job == nil {
+		return "", fmt.Errorf("no embedding backfill to resume")
+	}
+	job.Resume()
+	return formatBackfillStatus(job.Status()), nil
+}
+
+// runBackfillStatusCommand reports progress on the current backfill job.
+func (p *Plugin) runBackfillStatusCommand() (string, error) {
+	job := p.currentBackfillJob()
+	if job == nil {
+		return "No embedding backfill has been started.", nil
+	}
+	return formatBackfillStatus(job.Status()), nil
+}
+
+func formatBackfillStatus(status BackfillStatus) string {
+	state := "running"
+	switch {
+	case status.Err != nil:
+		state = fmt.Sprintf("failed (%s)", status.Err)
+	case status.Done:
+		state = "finished"
+	case status.Paused:
+		state = "paused"
+	}
+	return fmt.Sprintf("Backfill %s: %d/%d posts embedded.", state, status.PostsProcessed, status.PostsTotal)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+// runMigrateListCommand renders the `/mattermost-plugin-ai migrate list`
+// output: every known migration with its applied/pending state.
+func (p *Plugin) runMigrateListCommand() (string, error) {
+	statuses, err := p.ListMigrations()
+	if err != nil {
+		return "", fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	out := "| Version | Name | Status |\n| --- | --- | --- |\n"
+	for _, s := range statuses {
+		status := "pending"
+		if s.Applied {
+			status = "applied"
+		}
+		out += fmt.Sprintf("| %d | %s | %s |\n", s.Version, s.Name, status)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+)
+
+// configuration captures the admin console settings for this plugin. Treat
+// it as immutable once obtained via getConfiguration — OnConfigurationChange
+// always swaps in a whole new instance rather than mutating this one.
+type configuration struct {
+	// EnableReplicaReads routes read-only queries (thread listings,
+	// SearchPosts) to the replica DB reported by pluginAPI.Store.GetReplicaDB,
+	// falling back to the master if none is configured or reachable. Off by
+	// default so upgrading doesn't silently change read consistency.
+	EnableReplicaReads bool
+
+	// AssetStoreBackend selects where LLM attachments (asset.go) are stored:
+	// "postgres" (the default, used when empty) keeps them as Postgres large
+	// objects alongside the plugin's own tables; "s3" stores them in an
+	// S3-compatible bucket. See setupAssetStore.
+	AssetStoreBackend string
+
+	// AssetStoreS3Bucket is the bucket assets are stored in when
+	// AssetStoreBackend is "s3".
+	AssetStoreS3Bucket string
+
+	// AssetStoreS3Prefix is prepended to every object key when
+	// AssetStoreBackend is "s3", so attachments can share a bucket with
+	// other data.
+	AssetStoreS3Prefix string
+}
+
+// getConfiguration returns the active configuration. Safe for concurrent
+// use; never returns nil, so callers don't need a nil check before reading a
+// field.
+func (p *Plugin) getConfiguration() *configuration {
+	configValue := p.configuration.Load()
+	if configValue == nil {
+		return &configuration{}
+	}
+	return configValue
+}
+
+// setConfiguration atomically replaces the active configuration, allowing it
+// to be updated while the plugin is running.
+func (p *Plugin) setConfiguration(configuration *configuration) {
+	if old := p.configuration.Load(); old != nil && reflect.DeepEqual(old, configuration) {
+		return
+	}
+
+	p.configuration.Store(configuration)
+}
+
+// OnConfigurationChange is invoked when configuration changes may have
+// occurred. This plugin's configuration is loaded from the Mattermost server
+// configuration, as part of the plugin's settings schema.
+func (p *Plugin) OnConfigurationChange() error {
+	var configuration = new(configuration)
+
+	if err := p.API.LoadPluginConfiguration(configuration); err != nil {
+		return err
+	}
+
+	p.setConfiguration(configuration)
+
+	return nil
+}
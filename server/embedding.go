@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNoEmbeddingProvider is returned by embedding-dependent operations
+// (saveEmbedding, SearchPosts, backfilling) when no embedding provider has
+// been configured yet. getEmbeddingProvider legitimately returns nil in that
+// state — SetupDB treats it as optional startup config — so every other
+// reader must check for it too rather than dereferencing a nil provider.
+var ErrNoEmbeddingProvider = errors.New("no embedding provider configured")
+
+// EmbeddingProvider generates embeddings for a batch of post bodies. Each
+// provider owns its own table, named after Name() and Dimensions(), so
+// switching models never requires reinterpreting old vectors at a
+// different dimensionality.
+type EmbeddingProvider interface {
+	Name() string
+	Dimensions() int
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// legacyEmbeddingDimensions is the fixed dimension of LLM_Post_Embeddings,
+// the single embeddings table used before the per-provider refactor (see
+// migrateLegacyEmbeddings).
+const legacyEmbeddingDimensions = 768
+
+var embeddingTableNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// embeddingTableName returns the per-provider embeddings table, e.g.
+// LLM_Post_Embeddings_openai_text_embedding_3_small_1536.
+func embeddingTableName(provider EmbeddingProvider) string {
+	name := embeddingTableNameSanitizer.ReplaceAllString(strings.ToLower(provider.Name()), "_")
+	return fmt.Sprintf("LLM_Post_Embeddings_%s_%d", name, provider.Dimensions())
+}
+
+// embeddingStagingTableName returns the CopyFrom staging table that sits in
+// front of embeddingTableName (see BackfillJob).
+func embeddingStagingTableName(provider EmbeddingProvider) string {
+	return embeddingTableName(provider) + "_Staging"
+}
+
+// ensureEmbeddingTable creates the provider's embedding and staging tables
+// if they don't already exist, and records the provider in
+// LLM_Embedding_Providers so `migrate list`-style tooling and re-embed jobs
+// can discover what's been indexed. Safe to call on every startup.
+func (p *Plugin) ensureEmbeddingTable(provider EmbeddingProvider) error {
+	table := embeddingTableName(provider)
+	stagingTable := embeddingStagingTableName(provider)
+
+	if _, err := p.dbMaster.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			PostID TEXT NOT NULL REFERENCES Posts(ID) PRIMARY KEY,
+			Embedding vector(%d)
+		);
+	`, table, provider.Dimensions())); err != nil {
+		return fmt.Errorf("failed to create embedding table %s: %w", table, err)
+	}
+
+	if _, err := p.dbMaster.Exec(fmt.Sprintf(`
+		CREATE INDEX IF NOT EXISTS idx_%s_embedding_hnsw
+		ON %s USING hnsw (Embedding vector_cosine_ops);
+	`, strings.ToLower(table), table)); err != nil {
+		return fmt.Errorf("failed to index embedding table %s: %w", table, err)
+	}
+
+	if _, err := p.dbMaster.Exec(fmt.Sprintf(`
+		CREATE UNLOGGED TABLE IF NOT EXISTS %s (
+			PostID TEXT NOT NULL,
+			Embedding vector(%d)
+		);
+	`, stagingTable, provider.Dimensions())); err != nil {
+		return fmt.Errorf("failed to create embedding staging table %s: %w", stagingTable, err)
+	}
+
+	if _, err := p.dbMaster.Exec(`
+		INSERT INTO LLM_Embedding_Providers (Name, Dimensions, TableName)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (Name, Dimensions) DO UPDATE SET TableName = EXCLUDED.TableName
+	`, provider.Name(), provider.Dimensions(), table); err != nil {
+		return fmt.Errorf("failed to register embedding provider: %w", err)
+	}
+
+	return nil
+}
+
+// getEmbeddingProvider returns the active embedding provider. p.embeddingProvider
+// is mutated under p.mu by SwitchEmbeddingProvider, so every reader goes
+// through this accessor rather than reading the field directly.
+func (p *Plugin) getEmbeddingProvider() EmbeddingProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.embeddingProvider
+}
+
+// setEmbeddingProvider atomically swaps the active embedding provider.
+func (p *Plugin) setEmbeddingProvider(provider EmbeddingProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.embeddingProvider = provider
+}
+
+// migrateLegacyEmbeddings copies rows from LLM_Post_Embeddings — the single,
+// fixed-dimension table used before providers got their own table — into
+// provider's table, if the legacy table still exists and its dimensions
+// match. Instances upgrading from before the per-provider refactor would
+// otherwise lose access to everything they'd already indexed, since this
+// copy is the only remaining read of LLM_Post_Embeddings: search.go,
+// saveEmbedding and backfill.go use the per-provider tables. Safe to call on every startup: once the legacy rows are copied
+// over, ON CONFLICT DO NOTHING makes later calls a no-op. Dimension
+// mismatches (the active provider isn't the old 768-dimensional one) are
+// left untouched rather than guessed at.
+func (p *Plugin) migrateLegacyEmbeddings(provider EmbeddingProvider) error {
+	var exists bool
+	if err := p.dbMaster.Get(&exists, `
+		SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'llm_post_embeddings')
+	`); err != nil {
+		return fmt.Errorf("failed to check for legacy embedding table: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	if provider.Dimensions() != legacyEmbeddingDimensions {
+		p.API.LogWarn("legacy LLM_Post_Embeddings table exists but its dimensions don't match the active provider; leaving it in place",
+			"provider", provider.Name(), "providerDimensions", provider.Dimensions(), "legacyDimensions", legacyEmbeddingDimensions)
+		return nil
+	}
+
+	table := embeddingTableName(provider)
+	res, err := p.dbMaster.Exec(fmt.Sprintf(`
+		INSERT INTO %s (PostID, Embedding)
+		SELECT PostID, Embedding FROM LLM_Post_Embeddings
+		ON CONFLICT (PostID) DO NOTHING
+	`, table))
+	if err != nil {
+		return fmt.Errorf("failed to migrate legacy embeddings into %s: %w", table, err)
+	}
+
+	if copied, err := res.RowsAffected(); err == nil && copied > 0 {
+		p.API.LogInfo("migrated legacy embeddings into per-provider table", "table", table, "rows", copied)
+	}
+
+	return nil
+}
+
+// saveEmbedding upserts a post's embedding into the active provider's table.
+func (p *Plugin) saveEmbedding(postID string, embedding []float32) error {
+	provider := p.getEmbeddingProvider()
+	if provider == nil {
+		return ErrNoEmbeddingProvider
+	}
+
+	_, err := p.execBuilder(p.builder.
+		Insert(embeddingTableName(provider)).
+		SetMap(map[string]interface{}{
+			"PostID":    postID,
+			"Embedding": postgresEmbeddingFormat(embedding),
+		}).
+		Suffix("ON CONFLICT (PostID) DO UPDATE SET Embedding = EXCLUDED.Embedding"),
+	)
+	return err
+}
+
+// SwitchEmbeddingProvider backfills newProvider's table for channelIDs in
+// the background while queries keep serving from the current provider, then
+// atomically flips p.embeddingProvider once the backfill catches up. This
+// lets admins change embedding models without a search outage.
+func (p *Plugin) SwitchEmbeddingProvider(ctx context.Context, newProvider EmbeddingProvider, channelIDs []string, batchSize int) error {
+	if err := p.ensureEmbeddingTable(newProvider); err != nil {
+		return err
+	}
+
+	job, err := p.backfillToProvider(ctx, newProvider, channelIDs, batchSize)
+	if err != nil {
+		return err
+	}
+	if embeddingTableName(job.provider) != embeddingTableName(newProvider) {
+		return fmt.Errorf("another embedding backfill (%s) is already running", job.provider.Name())
+	}
+
+	job.onComplete = func() {
+		p.setEmbeddingProvider(newProvider)
+		p.API.LogInfo("switched embedding provider", "provider", newProvider.Name(), "table", embeddingTableName(newProvider))
+	}
+
+	return nil
+}
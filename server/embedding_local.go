@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// localEmbeddingProvider embeds posts via an HTTP sentence-transformers
+// server run alongside the Mattermost deployment, for operators who don't
+// want post content leaving their network.
+type localEmbeddingProvider struct {
+	httpClient *http.Client
+	url        string
+	modelName  string
+	dimensions int
+}
+
+func newLocalEmbeddingProvider(url, modelName string, dimensions int) *localEmbeddingProvider {
+	return &localEmbeddingProvider{
+		httpClient: http.DefaultClient,
+		url:        url,
+		modelName:  modelName,
+		dimensions: dimensions,
+	}
+}
+
+func (l *localEmbeddingProvider) Name() string {
+	return "local_" + l.modelName
+}
+
+func (l *localEmbeddingProvider) Dimensions() int {
+	return l.dimensions
+}
+
+type localEmbeddingRequest struct {
+	Inputs []string `json:"inputs"`
+}
+
+func (l *localEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(localEmbeddingRequest{Inputs: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal local embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build local embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call local embedding server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("local embedding server returned status %d", resp.StatusCode)
+	}
+
+	var embeddings [][]float32
+	if err := json.NewDecoder(resp.Body).Decode(&embeddings); err != nil {
+		return nil, fmt.Errorf("failed to decode local embedding response: %w", err)
+	}
+
+	return embeddings, nil
+}
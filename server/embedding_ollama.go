@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ollamaEmbeddingProvider embeds posts via a locally-running Ollama server's
+// /api/embeddings endpoint, one request per post (Ollama has no batch
+// embeddings endpoint as of writing).
+type ollamaEmbeddingProvider struct {
+	httpClient *http.Client
+	url        string
+	model      string
+	dimensions int
+}
+
+func newOllamaEmbeddingProvider(url, model string, dimensions int) *ollamaEmbeddingProvider {
+	return &ollamaEmbeddingProvider{
+		httpClient: http.DefaultClient,
+		url:        url,
+		model:      model,
+		dimensions: dimensions,
+	}
+}
+
+func (o *ollamaEmbeddingProvider) Name() string {
+	return "ollama_" + o.model
+}
+
+func (o *ollamaEmbeddingProvider) Dimensions() int {
+	return o.dimensions
+}
+
+type ollamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type ollamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+func (o *ollamaEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embedding, err := o.embedOne(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed post %d: %w", i, err)
+		}
+		embeddings[i] = embedding
+	}
+
+	return embeddings, nil
+}
+
+func (o *ollamaEmbeddingProvider) embedOne(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(ollamaEmbeddingRequest{Model: o.model, Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ollama embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url+"/api/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ollama embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var out ollamaEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+
+	return out.Embedding, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// openAIEmbeddingProvider embeds posts via the OpenAI embeddings API, e.g.
+// text-embedding-3-small (1536 dims) or text-embedding-3-large (3072 dims).
+type openAIEmbeddingProvider struct {
+	client     *openai.Client
+	model      openai.EmbeddingModel
+	dimensions int
+}
+
+func newOpenAIEmbeddingProvider(apiKey string, model openai.EmbeddingModel, dimensions int) *openAIEmbeddingProvider {
+	return &openAIEmbeddingProvider{
+		client:     openai.NewClient(apiKey),
+		model:      model,
+		dimensions: dimensions,
+	}
+}
+
+func (o *openAIEmbeddingProvider) Name() string {
+	return "openai_" + string(o.model)
+}
+
+func (o *openAIEmbeddingProvider) Dimensions() int {
+	return o.dimensions
+}
+
+func (o *openAIEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := o.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input:      texts,
+		Model:      o.model,
+		Dimensions: o.dimensions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create openai embeddings: %w", err)
+	}
+
+	embeddings := make([][]float32, len(resp.Data))
+	for i, d := range resp.Data {
+		embeddings[i] = d.Embedding
+	}
+
+	return embeddings, nil
+}
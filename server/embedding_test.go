@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeEmbeddingProvider struct {
+	name       string
+	dimensions int
+}
+
+func fakeProvider(name string, dimensions int) fakeEmbeddingProvider {
+	return fakeEmbeddingProvider{name: name, dimensions: dimensions}
+}
+
+func (f fakeEmbeddingProvider) Name() string       { return f.name }
+func (f fakeEmbeddingProvider) Dimensions() int    { return f.dimensions }
+func (f fakeEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func TestEmbeddingTableName(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider EmbeddingProvider
+		want     string
+	}{
+		{"simple name", fakeProvider("local", 384), "LLM_Post_Embeddings_local_384"},
+		{"sanitizes punctuation", fakeProvider("openai/text-embedding-3-small", 1536), "LLM_Post_Embeddings_openai_text_embedding_3_small_1536"},
+		{"lowercases", fakeProvider("Ollama", 768), "LLM_Post_Embeddings_ollama_768"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := embeddingTableName(tt.provider); got != tt.want {
+				t.Errorf("embeddingTableName(%q, %d) = %q, want %q", tt.provider.Name(), tt.provider.Dimensions(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddingStagingTableName(t *testing.T) {
+	provider := fakeProvider("local", 384)
+	want := "LLM_Post_Embeddings_local_384_Staging"
+	if got := embeddingStagingTableName(provider); got != want {
+		t.Errorf("embeddingStagingTableName(...) = %q, want %q", got, want)
+	}
+}
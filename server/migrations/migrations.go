@@ -0,0 +1,185 @@
+// Package migrations holds the ordered set of schema changes applied to the
+// plugin's Postgres tables. Each migration is applied at most once, tracked
+// by the version number recorded in LLM_SchemaVersion.
+package migrations
+
+import "github.com/jmoiron/sqlx"
+
+// Migration is a single, forward-only schema change. Version must be unique
+// and migrations are applied in ascending Version order.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sqlx.Tx) error
+}
+
+// All is the ordered list of migrations. Append new entries to the end;
+// never renumber or remove an already-released migration.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "create_llm_postmeta",
+		Up: func(tx *sqlx.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS LLM_PostMeta (
+					RootPostID TEXT NOT NULL REFERENCES Posts(ID) ON DELETE CASCADE PRIMARY KEY,
+					Title TEXT NOT NULL
+				);
+			`)
+			return err
+		},
+	},
+	{
+		Version: 2,
+		Name:    "drop_llm_threads_rootpostid_fkey",
+		Up: func(tx *sqlx.Tx) error {
+			_, err := tx.Exec(`ALTER TABLE IF EXISTS LLM_Threads DROP CONSTRAINT IF EXISTS llm_threads_rootpostid_fkey;`)
+			return err
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_llm_post_embeddings",
+		Up: func(tx *sqlx.Tx) error {
+			if _, err := tx.Exec(`CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS LLM_Post_Embeddings (
+					PostID TEXT NOT NULL REFERENCES Posts(ID) PRIMARY KEY,
+					Embedding vector(768)
+				);
+			`)
+			return err
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_post_search_indexes",
+		Up: func(tx *sqlx.Tx) error {
+			// HNSW gives sub-linear approximate nearest neighbor search over
+			// the embedding column; cosine distance matches the <=> operator
+			// used by SearchPosts.
+			if _, err := tx.Exec(`
+				CREATE INDEX IF NOT EXISTS idx_llm_post_embeddings_embedding_hnsw
+				ON LLM_Post_Embeddings USING hnsw (Embedding vector_cosine_ops);
+			`); err != nil {
+				return err
+			}
+
+			// Mirrors Mattermost's own idx_posts_message_txt full-text index,
+			// scoped to a GIN index so to_tsquery lookups in SearchPosts stay fast.
+			_, err := tx.Exec(`
+				CREATE INDEX IF NOT EXISTS idx_posts_message_txt_llm
+				ON Posts USING gin (to_tsvector('english', Message));
+			`)
+			return err
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create_llm_post_embeddings_staging",
+		Up: func(tx *sqlx.Tx) error {
+			// Unlogged + no FK/PK: BackfillEmbeddings used to bulk-load into
+			// this table with pgx CopyFrom, then move rows into
+			// LLM_Post_Embeddings with a single upsert. Superseded by
+			// version 9 once embeddings moved to per-provider tables (see
+			// embeddingStagingTableName) — kept here unmodified since
+			// already-released migrations are never edited or removed.
+			_, err := tx.Exec(`
+				CREATE UNLOGGED TABLE IF NOT EXISTS LLM_Post_Embeddings_Staging (
+					PostID TEXT NOT NULL,
+					Embedding vector(768)
+				);
+			`)
+			return err
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_llm_assets",
+		Up: func(tx *sqlx.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS LLM_Assets (
+					AssetID TEXT NOT NULL PRIMARY KEY,
+					RootPostID TEXT NOT NULL REFERENCES Posts(ID) ON DELETE CASCADE,
+					Kind TEXT NOT NULL,
+					MimeType TEXT NOT NULL,
+					CreatedAt BIGINT NOT NULL
+				);
+			`)
+			return err
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_llm_asset_objects",
+		Up: func(tx *sqlx.Tx) error {
+			// Maps our opaque AssetID to the OID the Postgres large-object
+			// backend (postgresAssetStore) allocates internally.
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS LLM_Asset_Objects (
+					AssetID TEXT NOT NULL REFERENCES LLM_Assets(AssetID) ON DELETE CASCADE PRIMARY KEY,
+					OID OID NOT NULL
+				);
+			`)
+			return err
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_llm_embedding_providers",
+		Up: func(tx *sqlx.Tx) error {
+			// Per-provider embedding tables themselves (LLM_Post_Embeddings_<provider>_<dim>)
+			// are created on demand by ensureEmbeddingTable, since which
+			// providers exist depends on plugin configuration, not a
+			// fixed schema version.
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS LLM_Embedding_Providers (
+					Name TEXT NOT NULL,
+					Dimensions INT NOT NULL,
+					TableName TEXT NOT NULL,
+					PRIMARY KEY (Name, Dimensions)
+				);
+			`)
+			return err
+		},
+	},
+	{
+		Version: 9,
+		Name:    "drop_llm_post_embeddings_staging",
+		Up: func(tx *sqlx.Tx) error {
+			// The fixed-name staging table from version 5 was replaced by
+			// per-provider staging tables (embeddingStagingTableName),
+			// created on demand in ensureEmbeddingTable alongside their
+			// embeddings table. It's only ever a transient CopyFrom buffer —
+			// nothing durable lives here, unlike LLM_Post_Embeddings itself,
+			// which migrateLegacyEmbeddings still reads from.
+			_, err := tx.Exec(`DROP TABLE IF EXISTS LLM_Post_Embeddings_Staging;`)
+			return err
+		},
+	},
+}
+
+// Latest returns the highest version number known to this build.
+func Latest() int {
+	latest := 0
+	for _, m := range All {
+		if m.Version > latest {
+			latest = m.Version
+		}
+	}
+	return latest
+}
+
+// Pending returns the migrations with a Version greater than current,
+// sorted ascending by Version.
+func Pending(current int) []Migration {
+	var pending []Migration
+	for _, m := range All {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
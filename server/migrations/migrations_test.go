@@ -0,0 +1,45 @@
+package migrations
+
+import "testing"
+
+func TestLatest(t *testing.T) {
+	var want int
+	for _, m := range All {
+		if m.Version > want {
+			want = m.Version
+		}
+	}
+
+	if got := Latest(); got != want {
+		t.Errorf("Latest() = %d, want %d", got, want)
+	}
+}
+
+func TestPending(t *testing.T) {
+	current := Latest()
+
+	if pending := Pending(current); len(pending) != 0 {
+		t.Errorf("Pending(%d) = %v, want none pending at the latest version", current, pending)
+	}
+
+	if pending := Pending(0); len(pending) != len(All) {
+		t.Errorf("Pending(0) returned %d migrations, want all %d", len(pending), len(All))
+	}
+
+	pending := Pending(current - 1)
+	if len(pending) != 1 {
+		t.Fatalf("Pending(%d) returned %d migrations, want 1", current-1, len(pending))
+	}
+	if pending[0].Version != current {
+		t.Errorf("Pending(%d) = version %d, want %d", current-1, pending[0].Version, current)
+	}
+}
+
+func TestPendingIsSortedAscending(t *testing.T) {
+	pending := Pending(0)
+	for i := 1; i < len(pending); i++ {
+		if pending[i].Version <= pending[i-1].Version {
+			t.Fatalf("Pending(0) not sorted ascending: version %d follows %d", pending[i].Version, pending[i-1].Version)
+		}
+	}
+}
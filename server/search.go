@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// rrfK is the reciprocal-rank-fusion constant: lower values weight the
+// top of each candidate list more heavily, higher values flatten it.
+const rrfK = 60
+
+// searchCandidateMultiplier controls how many candidates we pull from each
+// of the vector and full-text searches before fusing, relative to the
+// number of results the caller asked for.
+const searchCandidateMultiplier = 5
+
+// SearchResult is a single fused hit from SearchPosts.
+type SearchResult struct {
+	PostID    string
+	ChannelID string
+	Message   string
+	CreateAt  int64
+	Score     float64
+}
+
+// resolveSearchLimits defaults limit to 20 when the caller passes a
+// non-positive value, and derives how many candidates to pull from each of
+// the vector and full-text searches before fusing.
+func resolveSearchLimits(limit int) (resolvedLimit, candidateLimit int) {
+	if limit <= 0 {
+		limit = 20
+	}
+	return limit, limit * searchCandidateMultiplier
+}
+
+// SearchPosts combines pgvector cosine similarity against the active
+// embedding provider's table with Postgres full-text search over
+// Posts.Message, merging the two candidate lists with reciprocal rank
+// fusion (score = Σ 1/(k+rank_i)) so posts that rank well on either signal
+// surface.
+func (p *Plugin) SearchPosts(ctx context.Context, query string, embedding []float32, channelIDs []string, limit int) ([]SearchResult, error) {
+	limit, candidateLimit := resolveSearchLimits(limit)
+	provider := p.getEmbeddingProvider()
+	if provider == nil {
+		return nil, ErrNoEmbeddingProvider
+	}
+
+	var results []SearchResult
+	rows, err := p.readDB().QueryxContext(ctx, fmt.Sprintf(`
+		WITH vector_candidates AS (
+			SELECT p.Id AS postid, ROW_NUMBER() OVER (ORDER BY e.Embedding <=> $1) AS rank
+			FROM %s e
+			JOIN Posts p ON p.Id = e.PostID
+			WHERE p.ChannelID = ANY($2) AND p.DeleteAt = 0
+			ORDER BY e.Embedding <=> $1
+			LIMIT $3
+		),
+		text_candidates AS (
+			SELECT p.Id AS postid,
+				ROW_NUMBER() OVER (ORDER BY ts_rank(to_tsvector('english', p.Message), plainto_tsquery('english', $4)) DESC) AS rank
+			FROM Posts p
+			WHERE p.ChannelID = ANY($2) AND p.DeleteAt = 0
+				AND to_tsvector('english', p.Message) @@ plainto_tsquery('english', $4)
+			ORDER BY rank
+			LIMIT $3
+		),
+		fused AS (
+			SELECT postid, SUM(1.0 / ($5 + rank)) AS score
+			FROM (
+				SELECT * FROM vector_candidates
+				UNION ALL
+				SELECT * FROM text_candidates
+			) candidates
+			GROUP BY postid
+		)
+		SELECT p.Id AS postid, p.ChannelID AS channelid, p.Message AS message, p.CreateAt AS createat, f.score AS score
+		FROM fused f
+		JOIN Posts p ON p.Id = f.postid
+		ORDER BY f.score DESC
+		LIMIT $6
+	`, embeddingTableName(provider)), postgresEmbeddingFormat(embedding), pq.Array(channelIDs), candidateLimit, query, rrfK, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.PostID, &r.ChannelID, &r.Message, &r.CreateAt, &r.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
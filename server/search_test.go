@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestResolveSearchLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int
+		wantLimit     int
+		wantCandidate int
+	}{
+		{"positive limit", 10, 10, 10 * searchCandidateMultiplier},
+		{"zero defaults to 20", 0, 20, 20 * searchCandidateMultiplier},
+		{"negative defaults to 20", -5, 20, 20 * searchCandidateMultiplier},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLimit, gotCandidate := resolveSearchLimits(tt.limit)
+			if gotLimit != tt.wantLimit {
+				t.Errorf("resolveSearchLimits(%d) limit = %d, want %d", tt.limit, gotLimit, tt.wantLimit)
+			}
+			if gotCandidate != tt.wantCandidate {
+				t.Errorf("resolveSearchLimits(%d) candidateLimit = %d, want %d", tt.limit, gotCandidate, tt.wantCandidate)
+			}
+		})
+	}
+}
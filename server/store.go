@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-ai/server/migrations"
 	"github.com/mattermost/mattermost/server/public/model"
 )
 
@@ -26,80 +28,187 @@ func (p *Plugin) SetupDB() error {
 	if err != nil {
 		return err
 	}
-	p.db = sqlx.NewDb(origDB, p.pluginAPI.Store.DriverName())
+	p.dbMaster = sqlx.NewDb(origDB, p.pluginAPI.Store.DriverName())
+
+	if p.getConfiguration().EnableReplicaReads {
+		if replicaDB, err := p.pluginAPI.Store.GetReplicaDB(); err == nil && replicaDB != nil {
+			p.dbReplica = sqlx.NewDb(replicaDB, p.pluginAPI.Store.DriverName())
+		} else if err != nil {
+			p.API.LogWarn("no replica DB available, reads will use the master", "error", err.Error())
+		}
+	}
 
 	builder := sq.StatementBuilder.PlaceholderFormat(sq.Question)
 	builder = builder.PlaceholderFormat(sq.Dollar)
 	p.builder = builder
 
-	if err := p.SetupTables(); err != nil {
-		return fmt.Errorf("failed to setup tables: %w", err)
+	if err := p.runMigrations(); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	if err := p.setupEmbeddingStorage(768); err != nil {
-		return fmt.Errorf("failed to setup embedding storage: %w", err)
+	if provider := p.getEmbeddingProvider(); provider != nil {
+		if err := p.ensureEmbeddingTable(provider); err != nil {
+			return fmt.Errorf("failed to set up embedding storage: %w", err)
+		}
+		if err := p.migrateLegacyEmbeddings(provider); err != nil {
+			return fmt.Errorf("failed to migrate legacy embeddings: %w", err)
+		}
+	}
+
+	if err := p.setupAssetStore(context.Background()); err != nil {
+		return fmt.Errorf("failed to set up asset storage: %w", err)
 	}
 
 	return nil
 }
 
-func (p *Plugin) doQuery(dest interface{}, b builder) error {
-	sqlString, args, err := b.ToSql()
+// runMigrations brings LLM_SchemaVersion up to migrations.Latest(), applying
+// any pending migrations in order. Each migration runs in its own
+// transaction so a failure partway through a migration doesn't mark it
+// applied, while the version row is only advanced once its migration
+// commits successfully.
+func (p *Plugin) runMigrations() error {
+	if _, err := p.dbMaster.Exec(`
+		CREATE TABLE IF NOT EXISTS LLM_SchemaVersion (
+			Version INT NOT NULL
+		);
+	`); err != nil {
+		return fmt.Errorf("failed to create schema version table: %w", err)
+	}
+
+	current, err := p.currentSchemaVersionForUpdate()
 	if err != nil {
-		return fmt.Errorf("failed to build sql: %w", err)
+		return fmt.Errorf("failed to read schema version: %w", err)
 	}
 
-	sqlString = p.db.Rebind(sqlString)
+	for _, m := range migrations.Pending(current) {
+		if err := p.applyMigration(m); err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
 
-	return sqlx.Select(p.db, dest, sqlString, args...)
+	return nil
 }
 
-func (p *Plugin) execBuilder(b builder) (sql.Result, error) {
-	sqlString, args, err := b.ToSql()
+// currentSchemaVersionForUpdate reads the LLM_SchemaVersion row (creating it
+// at version 0 if absent). Its row lock is released when it returns;
+// applyMigration re-reads the version under its own lock so concurrent
+// plugin instances don't apply the same migration twice.
+func (p *Plugin) currentSchemaVersionForUpdate() (int, error) {
+	tx, err := p.dbMaster.Beginx()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build sql: %w", err)
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	sqlString = p.db.Rebind(sqlString)
+	var version int
+	err = tx.Get(&version, `SELECT Version FROM LLM_SchemaVersion FOR UPDATE`)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := tx.Exec(`INSERT INTO LLM_SchemaVersion (Version) VALUES (0)`); err != nil {
+			return 0, err
+		}
+		version = 0
+	} else if err != nil {
+		return 0, err
+	}
 
-	return p.db.Exec(sqlString, args...)
+	return version, tx.Commit()
 }
 
-func (p *Plugin) setupEmbeddingStorage(embeddingLength int) error {
-	if _, err := p.db.Exec(`CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
-		return fmt.Errorf("needs postgres vector extensions: %w", err)
+func (p *Plugin) applyMigration(m migrations.Migration) error {
+	tx, err := p.dbMaster.Beginx()
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	//TODO: FIX THE REFRENCE TO ADD THE CASCADE
-	if _, err := p.db.Exec(fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS LLM_Post_Embeddings (
-			PostID TEXT NOT NULL REFERENCES Posts(ID) PRIMARY KEY,
-			Embedding vector(%d)
-		);
-	`, embeddingLength)); err != nil {
-		return fmt.Errorf("failed to create table for post embeddings: %w", err)
+	var version int
+	if err := tx.Get(&version, `SELECT Version FROM LLM_SchemaVersion FOR UPDATE`); err != nil {
+		return err
+	}
+	if version >= m.Version {
+		return nil
 	}
 
-	return nil
+	if err := m.Up(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE LLM_SchemaVersion SET Version = $1`, m.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (p *Plugin) SetupTables() error {
-	if _, err := p.db.Exec(`
-		CREATE TABLE IF NOT EXISTS LLM_PostMeta (
-			RootPostID TEXT NOT NULL REFERENCES Posts(ID) ON DELETE CASCADE PRIMARY KEY,
-			Title TEXT NOT NULL
-		);
-	`); err != nil {
-		return fmt.Errorf("can't create llm titles table: %w", err)
+// MigrationStatus describes one migration for the `migrate list` slash command.
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+// ListMigrations reports the applied/pending state of every known
+// migration, for the `mattermost-plugin-ai migrate list` admin command.
+func (p *Plugin) ListMigrations() ([]MigrationStatus, error) {
+	var current int
+	if err := p.dbMaster.Get(&current, `SELECT Version FROM LLM_SchemaVersion`); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
 	}
 
-	// This fixes data retention issues when a post is deleted for an older version of the postmeta table.
-	// Migrate from the old table using `"INSERT INTO LLM_PostMeta(RootPostID, Title) SELECT RootPostID, Title from LLM_Threads"`
-	if _, err := p.db.Exec(`ALTER TABLE IF EXISTS LLM_Threads DROP CONSTRAINT IF EXISTS llm_threads_rootpostid_fkey;`); err != nil {
-		return fmt.Errorf("failed to migrate constraint: %w", err)
+	statuses := make([]MigrationStatus, 0, len(migrations.All))
+	for _, m := range migrations.All {
+		statuses = append(statuses, MigrationStatus{
+			Version: m.Version,
+			Name:    m.Name,
+			Applied: m.Version <= current,
+		})
 	}
 
-	return nil
+	return statuses, nil
+}
+
+func (p *Plugin) doQuery(dest interface{}, b builder) error {
+	return p.doQueryOn(p.dbMaster, dest, b)
+}
+
+// doQueryReplica runs a SELECT-only builder against the replica DB when one
+// is configured, falling back to the master otherwise. Use this for reads
+// that can tolerate replication lag (thread listings, similarity search);
+// anything in execBuilder keeps writing to the master.
+func (p *Plugin) doQueryReplica(dest interface{}, b builder) error {
+	return p.doQueryOn(p.readDB(), dest, b)
+}
+
+func (p *Plugin) doQueryOn(db *sqlx.DB, dest interface{}, b builder) error {
+	sqlString, args, err := b.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build sql: %w", err)
+	}
+
+	sqlString = db.Rebind(sqlString)
+
+	return sqlx.Select(db, dest, sqlString, args...)
+}
+
+// readDB returns the replica handle when replica reads are configured and
+// available, otherwise the master.
+func (p *Plugin) readDB() *sqlx.DB {
+	if p.dbReplica != nil {
+		return p.dbReplica
+	}
+	return p.dbMaster
+}
+
+func (p *Plugin) execBuilder(b builder) (sql.Result, error) {
+	sqlString, args, err := b.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sql: %w", err)
+	}
+
+	sqlString = p.dbMaster.Rebind(sqlString)
+
+	return p.dbMaster.Exec(sqlString, args...)
 }
 
 func (p *Plugin) saveTitleAsync(threadID, title string) {
@@ -129,7 +238,7 @@ type AIThread struct {
 
 func (p *Plugin) getAIThreads(dmChannelIDs []string) ([]AIThread, error) {
 	var posts []AIThread
-	if err := p.doQuery(&posts, p.builder.
+	if err := p.doQueryReplica(&posts, p.builder.
 		Select(
 			"p.Id",
 			"p.Message",
@@ -167,15 +276,3 @@ func postgresEmbeddingFormat(embedding []float32) string {
 
 	return result.String()
 }
-
-func (p *Plugin) saveEmbedding(postID string, embedding []float32) error {
-	_, err := p.execBuilder(p.builder.
-		Insert("LLM_Post_Embeddings").
-		SetMap(map[string]interface{}{
-			"PostID":    postID,
-			"Embedding": postgresEmbeddingFormat(embedding),
-		}).
-		Suffix("ON CONFLICT (PostID) DO UPDATE SET Embedding = EXCLUDED.Embedding"),
-	)
-	return err
-}